@@ -0,0 +1,316 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvFileVariable names the environment variable that points at the .env file to load.
+// When it is not set, oryxEnvFileDefault is used instead.
+const EnvFileVariable = "ORYX_ENV_FILE"
+
+const oryxEnvFileDefault = ".oryx.env"
+
+// Kind identifies how a registered configuration key's value should be parsed, so Validate can
+// report a bad value without the getter that eventually reads it having to panic.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindInt64
+	KindFloat
+	KindDuration
+)
+
+func (k Kind) expected() string {
+	switch k {
+	case KindInt:
+		return "an integer"
+	case KindInt64:
+		return "a 64-bit integer"
+	case KindFloat:
+		return "a floating point number"
+	case KindDuration:
+		return "a duration such as '30s' or '5m'"
+	default:
+		return "a string"
+	}
+}
+
+func (k Kind) parse(value string) error {
+	var err error
+	switch k {
+	case KindInt:
+		_, err = strconv.Atoi(value)
+	case KindInt64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case KindFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case KindDuration:
+		_, err = time.ParseDuration(value)
+	}
+	return err
+}
+
+// registeredConfigKey is the metadata recorded by Register for a single configuration key.
+type registeredConfigKey struct {
+	def      string
+	doc      string
+	required bool
+	kind     Kind
+}
+
+var (
+	configMutex    sync.Mutex
+	configOverride = map[string]string{}
+	configDefaults = map[string]registeredConfigKey{}
+
+	dotEnvOnce   sync.Once
+	dotEnvValues map[string]string
+)
+
+// SetOverride forces key to resolve to value ahead of the process environment and any
+// .env file, regardless of what is registered as its default. It is mainly useful for tests
+// and for callers that need to pin a value programmatically.
+func SetOverride(key, value string) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	configOverride[key] = value
+}
+
+// Register records key's default value, its documentation, whether it must be set by the time
+// Validate is called, and the Kind its value must parse as. Registering the same key twice
+// overwrites the previous registration.
+func Register(key, def, doc string, required bool, kind Kind) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	configDefaults[key] = registeredConfigKey{def: def, doc: doc, required: required, kind: kind}
+}
+
+// ResetForTest clears every registration, override and cached .env value, and drops any
+// issues recorded on DefaultValidator. It exists for common/envtest to call between test
+// cases so state registered or cached by one test can't leak into the next.
+func ResetForTest() {
+	configMutex.Lock()
+	configOverride = map[string]string{}
+	configDefaults = map[string]registeredConfigKey{}
+	dotEnvOnce = sync.Once{}
+	dotEnvValues = nil
+	configMutex.Unlock()
+
+	DefaultValidator.reset()
+}
+
+// resolveConfig resolves key through the layered sources, in order: explicit overrides,
+// process environment, .env file, registered defaults. It returns the resolved value, the
+// name of the source it came from, and whether it was found at all.
+func resolveConfig(key string) (value string, source string, ok bool) {
+	configMutex.Lock()
+	if v, found := configOverride[key]; found {
+		configMutex.Unlock()
+		return v, "override", true
+	}
+	rk, hasDefault := configDefaults[key]
+	configMutex.Unlock()
+
+	if v := GetEnvironmentVariable(key); v != "" {
+		return v, "env", true
+	}
+
+	if v, found := loadDotEnv()[key]; found {
+		return v, dotEnvPath(), true
+	}
+
+	if hasDefault && rk.def != "" {
+		return rk.def, "default", true
+	}
+
+	return "", "", false
+}
+
+func dotEnvPath() string {
+	if path := GetEnvironmentVariable(EnvFileVariable); path != "" {
+		return path
+	}
+	return "./" + oryxEnvFileDefault
+}
+
+// loadDotEnv reads the .env file once per process and caches the parsed key/value pairs.
+// A missing file is not an error: it simply means that layer contributes nothing.
+func loadDotEnv() map[string]string {
+	dotEnvOnce.Do(func() {
+		dotEnvValues = map[string]string{}
+		file, err := os.Open(dotEnvPath())
+		if err != nil {
+			return
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			key := strings.TrimSpace(parts[0])
+			value := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+			dotEnvValues[key] = value
+		}
+	})
+	return dotEnvValues
+}
+
+// getTyped resolves key and checks that its value parses as kind, recording a parse issue on
+// DefaultValidator instead of panicking when it doesn't. Callers that need that failure
+// surfaced immediately should call Validate or DefaultValidator.Err themselves.
+func getTyped(key string, kind Kind) (string, bool) {
+	value, source, ok := resolveConfig(key)
+	if !ok {
+		return "", false
+	}
+	if err := kind.parse(value); err != nil {
+		recordParseIssue(key, source, value, kind.expected())
+		return "", false
+	}
+	return value, true
+}
+
+// GetInt resolves key and parses it as an int. A value that fails to parse is reported through
+// DefaultValidator/Validate rather than by panicking.
+func GetInt(key string) int {
+	value, ok := getTyped(key, KindInt)
+	if !ok {
+		return 0
+	}
+	result, _ := strconv.Atoi(value)
+	return result
+}
+
+// GetInt64 resolves key and parses it as an int64. A value that fails to parse is reported
+// through DefaultValidator/Validate rather than by panicking.
+func GetInt64(key string) int64 {
+	value, ok := getTyped(key, KindInt64)
+	if !ok {
+		return 0
+	}
+	result, _ := strconv.ParseInt(value, 10, 64)
+	return result
+}
+
+// GetFloat resolves key and parses it as a float64. A value that fails to parse is reported
+// through DefaultValidator/Validate rather than by panicking.
+func GetFloat(key string) float64 {
+	value, ok := getTyped(key, KindFloat)
+	if !ok {
+		return 0
+	}
+	result, _ := strconv.ParseFloat(value, 64)
+	return result
+}
+
+// GetDuration resolves key and parses it with time.ParseDuration, e.g. "30s" or "5m". A value
+// that fails to parse is reported through DefaultValidator/Validate rather than by panicking.
+func GetDuration(key string) time.Duration {
+	value, ok := getTyped(key, KindDuration)
+	if !ok {
+		return 0
+	}
+	result, _ := time.ParseDuration(value)
+	return result
+}
+
+// GetStringSlice resolves key and splits it on sep, trimming whitespace and dropping empty
+// entries. It returns an empty, non-nil slice when key is not set.
+func GetStringSlice(key, sep string) []string {
+	value, _, ok := resolveConfig(key)
+	if !ok {
+		return []string{}
+	}
+	parts := strings.Split(value, sep)
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// GetEnum resolves key and, if its value doesn't match any of allowed, records a parse issue
+// on DefaultValidator instead of panicking.
+func GetEnum(key string, allowed []string) string {
+	value, source, ok := resolveConfig(key)
+	if !ok {
+		return ""
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value
+		}
+	}
+	recordParseIssue(key, source, value, fmt.Sprintf("one of %s", strings.Join(allowed, ", ")))
+	return ""
+}
+
+// Validate resolves every key registered with Register and reports, as a single error, every
+// required key that could not be resolved from any source plus every registered key whose
+// value didn't parse as its declared Kind. It also folds in anything already recorded on
+// DefaultValidator by the Get* functions or Validator.CheckBool, so a single Validate call
+// surfaces every misconfiguration regardless of how it was discovered. It returns nil when
+// nothing is wrong.
+func Validate() error {
+	configMutex.Lock()
+	keys := make([]string, 0, len(configDefaults))
+	entries := make(map[string]registeredConfigKey, len(configDefaults))
+	for key, rk := range configDefaults {
+		keys = append(keys, key)
+		entries[key] = rk
+	}
+	configMutex.Unlock()
+	sort.Strings(keys)
+
+	configErr := &ConfigError{}
+	for _, key := range keys {
+		rk := entries[key]
+		value, source, ok := resolveConfig(key)
+		if !ok {
+			if rk.required {
+				configErr.Add(key, "", fmt.Sprintf("required configuration key '%s' is not set", key))
+			}
+			continue
+		}
+		if err := rk.kind.parse(value); err != nil {
+			configErr.AddParseError(key, source, value, rk.kind.expected())
+		}
+	}
+
+	if validatorErr, ok := DefaultValidator.Err().(*ConfigError); ok {
+		configErr.Issues = append(configErr.Issues, validatorErr.Issues...)
+	}
+
+	sort.SliceStable(configErr.Issues, func(i, j int) bool {
+		return configErr.Issues[i].Key < configErr.Issues[j].Key
+	})
+
+	if len(configErr.Issues) == 0 {
+		return nil
+	}
+	return configErr
+}
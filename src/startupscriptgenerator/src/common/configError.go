@@ -0,0 +1,58 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigIssue describes a single problem found while resolving or validating configuration.
+// Value and Expected are only populated for parse failures; a missing required key leaves
+// both empty.
+type ConfigIssue struct {
+	Key      string
+	Source   string
+	Value    string
+	Expected string
+	Message  string
+}
+
+// ConfigError aggregates every ConfigIssue found during a single Validate call so that all
+// misconfigurations can be reported at once instead of failing on the first one.
+type ConfigError struct {
+	Issues []ConfigIssue
+}
+
+// Add appends an issue to the error.
+func (e *ConfigError) Add(key, source, message string) {
+	e.Issues = append(e.Issues, ConfigIssue{Key: key, Source: source, Message: message})
+}
+
+// AddParseError appends an issue for a key whose value could not be parsed as expected.
+func (e *ConfigError) AddParseError(key, source, value, expected string) {
+	e.Issues = append(e.Issues, newParseIssue(key, source, value, expected))
+}
+
+// newParseIssue builds the ConfigIssue for a key whose value didn't parse as expected. Shared
+// by ConfigError.AddParseError and the Validator so both report parse failures identically.
+func newParseIssue(key, source, value, expected string) ConfigIssue {
+	return ConfigIssue{
+		Key:      key,
+		Source:   source,
+		Value:    value,
+		Expected: expected,
+		Message:  fmt.Sprintf("invalid value '%s' for '%s' (source: %s): expected %s", value, key, source, expected),
+	}
+}
+
+func (e *ConfigError) Error() string {
+	lines := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		lines = append(lines, issue.Message)
+	}
+	return "configuration errors:\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,123 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetIntDoesNotPanicOnBadValue(t *testing.T) {
+	defer ResetForTest()
+	ResetForTest()
+	SetOverride("TEST_INT", "not-a-number")
+
+	result := GetInt("TEST_INT")
+
+	if result != 0 {
+		t.Fatalf("expected 0 for an unparseable int, got %d", result)
+	}
+}
+
+func TestValidateReportsUnparseableRegisteredValue(t *testing.T) {
+	defer ResetForTest()
+	ResetForTest()
+	Register("TEST_PORT", "", "the port to listen on", true, KindInt)
+	SetOverride("TEST_PORT", "not-a-number")
+
+	err := Validate()
+
+	if err == nil {
+		t.Fatal("expected Validate to report the unparseable value, got nil")
+	}
+	if !strings.Contains(err.Error(), "TEST_PORT") {
+		t.Fatalf("expected error to mention TEST_PORT, got: %v", err)
+	}
+}
+
+func TestValidateSurfacesGetterParseFailures(t *testing.T) {
+	defer ResetForTest()
+	ResetForTest()
+	SetOverride("TEST_TIMEOUT", "not-a-duration")
+
+	if d := GetDuration("TEST_TIMEOUT"); d != 0 {
+		t.Fatalf("expected 0 duration for an unparseable value, got %v", d)
+	}
+
+	err := Validate()
+	if err == nil {
+		t.Fatal("expected Validate to surface the GetDuration parse failure recorded on DefaultValidator")
+	}
+	if !strings.Contains(err.Error(), "TEST_TIMEOUT") {
+		t.Fatalf("expected error to mention TEST_TIMEOUT, got: %v", err)
+	}
+}
+
+func TestValidateOrdersIssuesByKeyDeterministically(t *testing.T) {
+	defer ResetForTest()
+	ResetForTest()
+	Register("TEST_Z_REQUIRED", "", "must be set", true, KindString)
+	Register("TEST_A_REQUIRED", "", "must be set", true, KindString)
+	Register("TEST_M_REQUIRED", "", "must be set", true, KindString)
+
+	var firstRun, secondRun []string
+	for i := 0; i < 5; i++ {
+		err := Validate()
+		if err == nil {
+			t.Fatal("expected Validate to report the missing required keys")
+		}
+		configErr, ok := err.(*ConfigError)
+		if !ok {
+			t.Fatalf("expected *ConfigError, got %T", err)
+		}
+
+		keys := make([]string, len(configErr.Issues))
+		for idx, issue := range configErr.Issues {
+			keys[idx] = issue.Key
+		}
+
+		if i == 0 {
+			firstRun = keys
+			continue
+		}
+		secondRun = keys
+		if !equalStringSlices(firstRun, secondRun) {
+			t.Fatalf("Validate's issue order was not deterministic: %v vs %v", firstRun, secondRun)
+		}
+	}
+
+	want := []string{"TEST_A_REQUIRED", "TEST_M_REQUIRED", "TEST_Z_REQUIRED"}
+	if !equalStringSlices(secondRun, want) {
+		t.Fatalf("expected issues sorted by key %v, got %v", want, secondRun)
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestValidateReportsMissingRequiredKey(t *testing.T) {
+	defer ResetForTest()
+	ResetForTest()
+	Register("TEST_REQUIRED", "", "must be set", true, KindString)
+
+	err := Validate()
+
+	if err == nil {
+		t.Fatal("expected Validate to report the missing required key")
+	}
+	if !strings.Contains(err.Error(), "TEST_REQUIRED") {
+		t.Fatalf("expected error to mention TEST_REQUIRED, got: %v", err)
+	}
+}
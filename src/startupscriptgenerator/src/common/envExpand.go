@@ -0,0 +1,103 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ExpandEnvironmentVariable resolves the value of key, then expands any $FOO, ${FOO},
+// ${FOO:-default} or ${FOO:?err} references found inside it, so manifests and generated
+// startup scripts can compose env vars out of one another. It returns an error instead of
+// panicking if it detects a cycle or hits an unset ${FOO:?err} reference.
+func ExpandEnvironmentVariable(key string) (string, error) {
+	return expandRef(key, nil)
+}
+
+// ExpandString expands any $FOO, ${FOO}, ${FOO:-default} or ${FOO:?err} references in s. It is
+// the entry point Oryx should use instead of hand-splicing environment values into generated
+// bash.
+func ExpandString(s string) (string, error) {
+	var firstErr error
+	result := os.Expand(s, func(token string) string {
+		if firstErr != nil {
+			return ""
+		}
+		value, err := expandFallback(token, nil)
+		if err != nil {
+			firstErr = err
+			return ""
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return result, nil
+}
+
+// expandRef resolves name through the process environment and recursively expands references
+// found inside its value, tracking the chain of keys being resolved so cycles can be detected
+// and reported as an error rather than causing infinite recursion.
+func expandRef(name string, stack []string) (string, error) {
+	for _, seen := range stack {
+		if seen == name {
+			return "", fmt.Errorf("cycle detected while expanding environment variable '%s': %s -> %s", name, strings.Join(stack, " -> "), name)
+		}
+	}
+
+	value := GetEnvironmentVariable(name)
+	stack = append(stack, name)
+
+	var firstErr error
+	result := os.Expand(value, func(token string) string {
+		if firstErr != nil {
+			return ""
+		}
+		expanded, err := expandFallback(token, stack)
+		if err != nil {
+			firstErr = err
+			return ""
+		}
+		return expanded
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return result, nil
+}
+
+// expandFallback handles the ${FOO:-default} and ${FOO:?err} forms; os.Expand only ever gives
+// us the raw text between ${ and }, so we split the operator out ourselves.
+func expandFallback(token string, stack []string) (string, error) {
+	if idx := strings.Index(token, ":-"); idx != -1 {
+		name, def := token[:idx], token[idx+2:]
+		value, err := expandRef(name, stack)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			return value, nil
+		}
+		return def, nil
+	}
+
+	if idx := strings.Index(token, ":?"); idx != -1 {
+		name, msg := token[:idx], token[idx+2:]
+		value, err := expandRef(name, stack)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			return value, nil
+		}
+		return "", fmt.Errorf("environment variable '%s' is not set: %s", name, msg)
+	}
+
+	return expandRef(token, stack)
+}
@@ -0,0 +1,49 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package common
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExpandStringAppliesDefaultFallback(t *testing.T) {
+	os.Unsetenv("TEST_FOO")
+	defer os.Unsetenv("TEST_FOO")
+
+	result, err := ExpandString("prefix-${TEST_FOO:-default}-suffix")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "prefix-default-suffix" {
+		t.Fatalf("expected 'prefix-default-suffix', got %q", result)
+	}
+}
+
+func TestExpandStringAppliesErrFallback(t *testing.T) {
+	os.Unsetenv("TEST_FOO")
+	defer os.Unsetenv("TEST_FOO")
+
+	_, err := ExpandString("prefix-${TEST_FOO:?must be set}-suffix")
+
+	if err == nil {
+		t.Fatal("expected an error for an unset ${FOO:?err} reference, got nil")
+	}
+}
+
+func TestExpandEnvironmentVariableDetectsCycleWithoutPanicking(t *testing.T) {
+	os.Setenv("TEST_A", "${TEST_B}")
+	os.Setenv("TEST_B", "${TEST_A}")
+	defer os.Unsetenv("TEST_A")
+	defer os.Unsetenv("TEST_B")
+
+	_, err := ExpandEnvironmentVariable("TEST_A")
+
+	if err == nil {
+		t.Fatal("expected a cycle error, got nil")
+	}
+}
@@ -0,0 +1,109 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+// Package envtest provides helpers for exercising code that reads environment variables
+// without leaking state between tests, mirroring the Setenv/Unsetenv/Clearenv/Environ shape
+// used across the Go stdlib env packages.
+package envtest
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"common"
+)
+
+// envtestMutex serializes the helpers below: they all mutate process-global state (os.Environ
+// and common's package-level config), so two tests running them at the same time -- which only
+// happens when one or both called t.Parallel() -- would stomp on each other. acquireExclusive
+// turns that race into an immediate, readable test failure instead of flaky cross-test state.
+var envtestMutex sync.Mutex
+
+// acquireExclusive reports whether it obtained exclusive access, returning a release func to
+// pair with it. It never blocks: a concurrent caller (not the caller waiting its turn) means
+// envtestMutex is already held by another test, most likely because both are running under
+// t.Parallel().
+func acquireExclusive() (release func(), ok bool) {
+	if !envtestMutex.TryLock() {
+		return nil, false
+	}
+	return envtestMutex.Unlock, true
+}
+
+// requireExclusive fails t immediately if another test is concurrently using one of these
+// helpers, and otherwise releases the lock once t (including its subtests) finishes.
+func requireExclusive(t *testing.T) {
+	t.Helper()
+	release, ok := acquireExclusive()
+	if !ok {
+		t.Fatalf("envtest: this helper mutates process-global state (os environ and common's config) and cannot be used while another test is using it concurrently; remove t.Parallel() from whichever test is sharing it")
+	}
+	t.Cleanup(release)
+}
+
+// Snapshot captures the current process environment and returns a func that restores it,
+// clearing anything set after the snapshot was taken. Intended for use with defer.
+func Snapshot() func() {
+	saved := os.Environ()
+
+	return func() {
+		os.Clearenv()
+		for _, kv := range saved {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) == 2 {
+				os.Setenv(parts[0], parts[1])
+			}
+		}
+	}
+}
+
+// WithEnv sets every key/value pair in kv for the duration of t, and via t.Cleanup restores each
+// key's previous value once t finishes (unsetting keys that were not set before). It also resets common's Register/SetOverride state and cached
+// .env values, both before and after t runs, so a key set here (commonly ORYX_ENV_FILE) can't
+// be served a previous test's cached config.
+func WithEnv(t *testing.T, kv map[string]string) {
+	requireExclusive(t)
+	common.ResetForTest()
+	t.Cleanup(common.ResetForTest)
+
+	for key, value := range kv {
+		key := key
+		previous, hadPrevious := os.LookupEnv(key)
+
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatalf("envtest: could not set %s: %v", key, err)
+		}
+
+		t.Cleanup(func() {
+			if hadPrevious {
+				os.Setenv(key, previous)
+			} else {
+				os.Unsetenv(key)
+			}
+		})
+	}
+}
+
+// Isolate clears every ORYX_* variable for the duration of t, restoring them once t finishes.
+// It also resets common's Register/SetOverride state and cached .env values, both before and
+// after t runs, so neither env vars nor config state leak into or out of t.
+func Isolate(t *testing.T) {
+	requireExclusive(t)
+	restore := Snapshot()
+	common.ResetForTest()
+	t.Cleanup(func() {
+		restore()
+		common.ResetForTest()
+	})
+
+	for _, kv := range os.Environ() {
+		key := strings.SplitN(kv, "=", 2)[0]
+		if strings.HasPrefix(key, "ORYX_") {
+			os.Unsetenv(key)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"common"
+)
+
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".oryx.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("could not write fixture: %v", err)
+	}
+	return path
+}
+
+func TestWithEnvDoesNotLeakCachedDotEnvBetweenCases(t *testing.T) {
+	t.Run("first case", func(t *testing.T) {
+		WithEnv(t, map[string]string{"ORYX_ENV_FILE": writeEnvFile(t, "GREETING=hello\n")})
+
+		if got := common.GetEnum("GREETING", []string{"hello", "goodbye"}); got != "hello" {
+			t.Fatalf("expected 'hello' from the first .env fixture, got %q", got)
+		}
+	})
+
+	t.Run("second case", func(t *testing.T) {
+		WithEnv(t, map[string]string{"ORYX_ENV_FILE": writeEnvFile(t, "GREETING=goodbye\n")})
+
+		// common caches the parsed .env file for the life of the process, so this would still
+		// return "hello" from the first case's fixture if that cache weren't reset between tests.
+		if got := common.GetEnum("GREETING", []string{"hello", "goodbye"}); got != "goodbye" {
+			t.Fatalf("expected the second test's .env value 'goodbye', got %q (stale cache from the first case)", got)
+		}
+	})
+}
+
+func TestSnapshotRestoresEnviron(t *testing.T) {
+	os.Setenv("ENVTEST_EXISTING", "before")
+	defer os.Unsetenv("ENVTEST_EXISTING")
+
+	restore := Snapshot()
+	os.Setenv("ENVTEST_EXISTING", "after")
+	os.Setenv("ENVTEST_NEW", "new")
+	defer os.Unsetenv("ENVTEST_NEW")
+
+	restore()
+
+	if got := os.Getenv("ENVTEST_EXISTING"); got != "before" {
+		t.Fatalf("expected ENVTEST_EXISTING to be restored to 'before', got %q", got)
+	}
+	if _, ok := os.LookupEnv("ENVTEST_NEW"); ok {
+		t.Fatal("expected ENVTEST_NEW, set after the snapshot, to be cleared by restore")
+	}
+}
+
+func TestIsolateClearsAndRestoresOryxVars(t *testing.T) {
+	os.Setenv("ORYX_SOMETHING", "value")
+	defer os.Unsetenv("ORYX_SOMETHING")
+
+	t.Run("isolated", func(t *testing.T) {
+		Isolate(t)
+
+		if _, ok := os.LookupEnv("ORYX_SOMETHING"); ok {
+			t.Fatal("expected ORYX_SOMETHING to be cleared inside Isolate")
+		}
+	})
+
+	if got := os.Getenv("ORYX_SOMETHING"); got != "value" {
+		t.Fatalf("expected ORYX_SOMETHING to be restored to 'value' after the subtest, got %q", got)
+	}
+}
+
+func TestAcquireExclusiveRejectsConcurrentUse(t *testing.T) {
+	release, ok := acquireExclusive()
+	if !ok {
+		t.Fatal("expected the first acquireExclusive to succeed")
+	}
+	defer release()
+
+	if _, ok := acquireExclusive(); ok {
+		t.Fatal("expected a concurrent acquireExclusive to fail while the first caller still holds it")
+	}
+}
@@ -0,0 +1,96 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"common"
+)
+
+// Resolver turns the reference portion of a "scheme:reference" value (e.g. the
+// "/run/secrets/foo" in "file:/run/secrets/foo") into the secret it points at.
+type Resolver interface {
+	Scheme() string
+	Resolve(ref string) (string, error)
+}
+
+var (
+	resolversMutex sync.Mutex
+	resolvers      = map[string]Resolver{}
+)
+
+// RegisterResolver adds r to the chain consulted by Get, keyed by r.Scheme(). Registering a
+// scheme that already has a resolver replaces it, which lets operators swap in a real
+// keyvaultResolver without touching Oryx's own code.
+func RegisterResolver(r Resolver) {
+	resolversMutex.Lock()
+	defer resolversMutex.Unlock()
+	resolvers[r.Scheme()] = r
+}
+
+func init() {
+	RegisterResolver(fileResolver{})
+	RegisterResolver(envResolver{})
+	RegisterResolver(keyvaultResolver{})
+}
+
+// resolve splits value into "scheme:reference" and hands the reference to the matching
+// Resolver. A value with no recognized scheme (including a plain, unprefixed secret) is
+// returned as-is.
+func resolve(value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+
+	resolversMutex.Lock()
+	r, ok := resolvers[scheme]
+	resolversMutex.Unlock()
+	if !ok {
+		return value, nil
+	}
+
+	return r.Resolve(ref)
+}
+
+// fileResolver implements "file:/path/to/secret", the shape used by Docker and Kubernetes
+// secret mounts.
+type fileResolver struct{}
+
+func (fileResolver) Scheme() string { return "file" }
+
+func (fileResolver) Resolve(ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", fmt.Errorf("secrets: could not read secret file '%s': %w", ref, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// envResolver implements "env:OTHER_VAR", for indirecting one env var through another.
+type envResolver struct{}
+
+func (envResolver) Scheme() string { return "env" }
+
+func (envResolver) Resolve(ref string) (string, error) {
+	return common.GetEnvironmentVariable(ref), nil
+}
+
+// keyvaultResolver implements "keyvault:https://...", the shape used to point at an Azure Key
+// Vault secret URI. Talking to Key Vault needs credentials and an SDK client that aren't part
+// of this package; operators who need this in production should RegisterResolver a
+// "keyvault" implementation backed by azidentity/azsecrets before calling Get.
+type keyvaultResolver struct{}
+
+func (keyvaultResolver) Scheme() string { return "keyvault" }
+
+func (keyvaultResolver) Resolve(ref string) (string, error) {
+	return "", fmt.Errorf("secrets: no keyvault resolver is registered for '%s'; call RegisterResolver with an Azure Key Vault-backed Resolver", ref)
+}
@@ -0,0 +1,112 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetResolvesFileScheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("could not write fixture: %v", err)
+	}
+	os.Setenv("TEST_SECRET_FILE", "file:"+path)
+	defer os.Unsetenv("TEST_SECRET_FILE")
+
+	s, err := Get("TEST_SECRET_FILE")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Reveal() != "from-file" {
+		t.Fatalf("expected 'from-file', got %q", s.Reveal())
+	}
+}
+
+func TestGetResolvesEnvScheme(t *testing.T) {
+	os.Setenv("TEST_SECRET_OTHER", "from-other-var")
+	os.Setenv("TEST_SECRET_ENV", "env:TEST_SECRET_OTHER")
+	defer os.Unsetenv("TEST_SECRET_OTHER")
+	defer os.Unsetenv("TEST_SECRET_ENV")
+
+	s, err := Get("TEST_SECRET_ENV")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Reveal() != "from-other-var" {
+		t.Fatalf("expected 'from-other-var', got %q", s.Reveal())
+	}
+}
+
+func TestGetPassesThroughUnrecognizedScheme(t *testing.T) {
+	os.Setenv("TEST_SECRET_PLAIN", "plaintext-value")
+	defer os.Unsetenv("TEST_SECRET_PLAIN")
+
+	s, err := Get("TEST_SECRET_PLAIN")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Reveal() != "plaintext-value" {
+		t.Fatalf("expected the raw value to pass through unchanged, got %q", s.Reveal())
+	}
+}
+
+func TestGetKeyvaultSchemeErrorsWithoutARegisteredResolver(t *testing.T) {
+	os.Setenv("TEST_SECRET_KV", "keyvault:https://example.vault.azure.net/secrets/foo")
+	defer os.Unsetenv("TEST_SECRET_KV")
+
+	_, err := Get("TEST_SECRET_KV")
+
+	if err == nil {
+		t.Fatal("expected an error since no keyvault resolver is registered")
+	}
+	if !strings.Contains(err.Error(), "keyvault") {
+		t.Fatalf("expected the error to mention the keyvault scheme, got: %v", err)
+	}
+}
+
+func TestIsSensitiveMatchesWellKnownSuffixes(t *testing.T) {
+	cases := map[string]bool{
+		"DB_PASSWORD":    true,
+		"API_TOKEN":      true,
+		"ENCRYPTION_KEY": true,
+		"CLIENT_SECRET":  true,
+		"APP_NAME":       false,
+	}
+
+	for key, want := range cases {
+		if got := IsSensitive(key); got != want {
+			t.Errorf("IsSensitive(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestRegisterSecretMarksArbitraryKeysSensitive(t *testing.T) {
+	if IsSensitive("CUSTOM_FIELD") {
+		t.Fatal("CUSTOM_FIELD should not be sensitive before RegisterSecret")
+	}
+
+	RegisterSecret("CUSTOM_FIELD")
+
+	if !IsSensitive("CUSTOM_FIELD") {
+		t.Fatal("expected CUSTOM_FIELD to be sensitive after RegisterSecret")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if got := Redact("APP_NAME", "my-app"); got != "my-app" {
+		t.Fatalf("expected a non-sensitive value to pass through, got %q", got)
+	}
+	if got := Redact("DB_PASSWORD", "hunter2"); got != "***" {
+		t.Fatalf("expected a sensitive value to be redacted, got %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+// Package secrets wraps common.GetEnvironmentVariable so that sensitive configuration never
+// ends up in a log line by accident, and so operators can point Oryx at Docker/Kubernetes
+// secret mounts or Azure Key Vault instead of embedding plaintext in the container environment.
+package secrets
+
+import (
+	"strings"
+	"sync"
+
+	"common"
+)
+
+// sensitiveSuffixes are the key name endings that are treated as secret even without an
+// explicit RegisterSecret call.
+var sensitiveSuffixes = []string{"_PASSWORD", "_TOKEN", "_KEY", "_SECRET"}
+
+var (
+	registeredMutex sync.Mutex
+	registered      = map[string]bool{}
+)
+
+// RegisterSecret marks key as sensitive regardless of whether its name matches one of the
+// well-known suffixes.
+func RegisterSecret(key string) {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+	registered[key] = true
+}
+
+// IsSensitive reports whether key is registered as a secret or matches a well-known sensitive
+// suffix such as _PASSWORD, _TOKEN, _KEY or _SECRET.
+func IsSensitive(key string) bool {
+	registeredMutex.Lock()
+	explicit := registered[key]
+	registeredMutex.Unlock()
+	if explicit {
+		return true
+	}
+
+	upper := strings.ToUpper(key)
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(upper, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Secret wraps a sensitive value so that it can be passed around and logged without
+// accidentally leaking its contents: String always prints "***", and the real value is only
+// reachable through Reveal.
+type Secret struct {
+	value string
+}
+
+func (s Secret) String() string {
+	return "***"
+}
+
+// GoString backs the %#v verb. Without it, fmt falls back to reflecting over Secret's fields
+// -- including the unexported value -- which would print the real secret despite String being
+// redacted.
+func (s Secret) GoString() string {
+	return "secrets.Secret{***}"
+}
+
+// MarshalText lets Secret be redacted when encoded by anything that prefers
+// encoding.TextMarshaler, e.g. encoding/json or encoding/xml.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte("***"), nil
+}
+
+// MarshalJSON redacts Secret for structured loggers and encoders that call it directly instead
+// of going through MarshalText.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"***"`), nil
+}
+
+// Reveal returns the underlying value. Callers should only do this right before using the
+// value, never before logging or printing it.
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+// Get resolves key through common.GetEnvironmentVariable, runs it through the resolver chain
+// (file:, env:, keyvault:, or any resolver added with RegisterResolver) and returns it as a
+// Secret. It does not require key to be registered with RegisterSecret.
+func Get(key string) (Secret, error) {
+	raw := common.GetEnvironmentVariable(key)
+	resolved, err := resolve(raw)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{value: resolved}, nil
+}
+
+// Redact returns value unchanged, unless key IsSensitive in which case it returns "***". Oryx's
+// logging paths should call this instead of echoing env values directly.
+func Redact(key, value string) string {
+	if IsSensitive(key) {
+		return "***"
+	}
+	return value
+}
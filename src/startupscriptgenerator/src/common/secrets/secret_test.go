@@ -0,0 +1,48 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newTestSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+func TestSecretDoesNotLeakViaGoStringVerb(t *testing.T) {
+	s := newTestSecret("super-secret-value")
+
+	formatted := fmt.Sprintf("%#v", s)
+
+	if strings.Contains(formatted, "super-secret-value") {
+		t.Fatalf("%%#v leaked the secret value: %s", formatted)
+	}
+}
+
+func TestSecretDoesNotLeakViaJSONMarshal(t *testing.T) {
+	s := newTestSecret("super-secret-value")
+
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(out), "super-secret-value") {
+		t.Fatalf("json.Marshal leaked the secret value: %s", out)
+	}
+}
+
+func TestSecretRevealReturnsRealValue(t *testing.T) {
+	s := newTestSecret("super-secret-value")
+
+	if s.Reveal() != "super-secret-value" {
+		t.Fatalf("expected Reveal to return the underlying value, got %q", s.Reveal())
+	}
+}
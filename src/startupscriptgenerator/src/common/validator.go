@@ -0,0 +1,94 @@
+// --------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT license.
+// --------------------------------------------------------------------------------------------
+
+package common
+
+import (
+	"strconv"
+	"sync"
+)
+
+// TryGetBooleanEnvironmentVariable is the non-panicking counterpart to
+// GetBooleanEnvironmentVariable. It returns the parsed value, whether key was set at all, and
+// a non-nil error if key was set to something that isn't a valid bool.
+func TryGetBooleanEnvironmentVariable(key string) (bool, bool, error) {
+	value := GetEnvironmentVariable(key)
+	if value == "" {
+		return false, false, nil
+	}
+
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		configErr := &ConfigError{}
+		configErr.AddParseError(key, "env", value, "'true' or 'false'")
+		return false, true, configErr
+	}
+	return result, true, nil
+}
+
+// MustGetBooleanEnvironmentVariable parses key as a bool, panicking with the *ConfigError
+// message from TryGetBooleanEnvironmentVariable if it is set but invalid.
+func MustGetBooleanEnvironmentVariable(key string) bool {
+	value, _, err := TryGetBooleanEnvironmentVariable(key)
+	if err != nil {
+		panic(err.Error())
+	}
+	return value
+}
+
+// Validator collects configuration problems found while probing env vars during startup so
+// that they can be reported together instead of aborting on the first one.
+type Validator struct {
+	mu     sync.Mutex
+	issues []ConfigIssue
+}
+
+// NewValidator returns an empty Validator.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// DefaultValidator is the package-level Validator that build/detect/run code should share
+// during startup so every misconfiguration ends up in the same report.
+var DefaultValidator = NewValidator()
+
+// CheckBool resolves key as a boolean, recording a parse failure on the validator instead of
+// panicking.
+func (v *Validator) CheckBool(key string) bool {
+	value, _, err := TryGetBooleanEnvironmentVariable(key)
+	if err != nil {
+		configErr := err.(*ConfigError)
+		v.mu.Lock()
+		v.issues = append(v.issues, configErr.Issues...)
+		v.mu.Unlock()
+	}
+	return value
+}
+
+// Err returns nil if no issues were recorded, or a single *ConfigError aggregating all of
+// them otherwise.
+func (v *Validator) Err() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &ConfigError{Issues: append([]ConfigIssue(nil), v.issues...)}
+}
+
+// reset drops every issue recorded so far. Used by ResetForTest between test cases.
+func (v *Validator) reset() {
+	v.mu.Lock()
+	v.issues = nil
+	v.mu.Unlock()
+}
+
+// recordParseIssue records a parse failure for key on DefaultValidator, which is how the
+// config Get* getters surface a bad value instead of panicking.
+func recordParseIssue(key, source, value, expected string) {
+	DefaultValidator.mu.Lock()
+	DefaultValidator.issues = append(DefaultValidator.issues, newParseIssue(key, source, value, expected))
+	DefaultValidator.mu.Unlock()
+}